@@ -0,0 +1,198 @@
+package goyeelight
+
+import (
+	"errors"
+	"image/color"
+	"time"
+)
+
+// ErrUnsupported is returned instead of sending a command when the target
+// bulb's discovered "support" list does not contain the underlying
+// method, rather than silently sending a command the bulb would reject.
+var ErrUnsupported = errors.New("goyeelight: method not supported by this bulb")
+
+// Capability describes what a given bulb model supports. CTMin/CTMax are
+// used to clamp color temperature; Background and NightLight only record
+// the model's features, and calls through Background or SetNightLight are
+// gated on the bulb's discovered support list, not on these flags.
+type Capability struct {
+	CTMin      int
+	CTMax      int
+	Background bool
+	NightLight bool
+}
+
+// modelCapabilities is keyed by the "model" string reported by SSDP
+// discovery or GetProp("model"). Models not listed fall back to the
+// package's default CT range with no background or night-light support.
+var modelCapabilities = map[string]Capability{
+	"mono":     {CTMin: 2700, CTMax: 2700},
+	"color":    {CTMin: 1700, CTMax: 6500},
+	"stripe":   {CTMin: 2700, CTMax: 6500},
+	"ceiling":  {CTMin: 2700, CTMax: 6500, NightLight: true},
+	"ceiling1": {CTMin: 2700, CTMax: 6500, NightLight: true},
+	"ceiling4": {CTMin: 2700, CTMax: 6500, Background: true, NightLight: true},
+	"bslamp":   {CTMin: 1700, CTMax: 6500, Background: true},
+	"bslamp2":  {CTMin: 1700, CTMax: 6500, Background: true},
+}
+
+// capability returns y's model capability, falling back to the default
+// CT range with no optional features for unknown or undiscovered models.
+func (y *Yeelight) capability() Capability {
+	if c, ok := modelCapabilities[y.Model]; ok {
+		return c
+	}
+	return Capability{CTMin: defaultCTMin, CTMax: defaultCTMax}
+}
+
+// clampCT clamps kelvin to the CT range y's model supports.
+func (y *Yeelight) clampCT(kelvin int) int {
+	c := y.capability()
+	switch {
+	case kelvin < c.CTMin:
+		return c.CTMin
+	case kelvin > c.CTMax:
+		return c.CTMax
+	default:
+		return kelvin
+	}
+}
+
+// supports reports whether method is in the bulb's discovered "support"
+// list. If the bulb wasn't discovered via Discover/DiscoverAsync/Listen,
+// Support is empty and supports always returns true so the check is a
+// no-op for hand-built instances.
+func (y *Yeelight) supports(method string) bool {
+	if len(y.Support) == 0 {
+		return true
+	}
+	for _, m := range y.Support {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// LightChannel is one of the two independently addressable light sources
+// a bulb can expose: the main light, and on bslamp/ceiling-style models,
+// the background light. Both implement the same set of state-changing
+// methods; which underlying command they send is the only difference.
+type LightChannel struct {
+	y      *Yeelight
+	prefix string // "" for the main channel, "bg_" for the background channel
+}
+
+// Main returns a handle to the bulb's primary light channel.
+func (y *Yeelight) Main() *LightChannel {
+	return &LightChannel{y: y}
+}
+
+// Background returns a handle to the bulb's background light channel.
+// Only bslamp2/3 and ceiling-series models support it; calls through it
+// return ErrUnsupported on bulbs whose discovered support list doesn't
+// include the corresponding bg_* method.
+func (y *Yeelight) Background() *LightChannel {
+	return &LightChannel{y: y, prefix: "bg_"}
+}
+
+// send builds the channel-prefixed method name, rejects it up front with
+// ErrUnsupported if the bulb didn't advertise support for it, and
+// otherwise sends it as a typed Command.
+func (c *LightChannel) send(name string, params ...interface{}) (string, error) {
+	method := c.prefix + name
+	if !c.y.supports(method) {
+		return "", ErrUnsupported
+	}
+	return c.y.send(c.y.command(method, params...))
+}
+
+// SetPower switches the channel on or off.
+func (c *LightChannel) SetPower(on bool, effect Effect, duration time.Duration) (string, error) {
+	power := "off"
+	if on {
+		power = "on"
+	}
+	return c.send("set_power", power, string(effect), int(duration/time.Millisecond))
+}
+
+// Toggle flips the channel's current power state.
+func (c *LightChannel) Toggle() (string, error) {
+	return c.send("toggle")
+}
+
+// SetColor changes the channel's RGB color.
+func (c *LightChannel) SetColor(col color.RGBA, effect Effect, duration time.Duration) (string, error) {
+	rgb := int(col.R)<<16 | int(col.G)<<8 | int(col.B)
+	return c.send("set_rgb", rgb, string(effect), int(duration/time.Millisecond))
+}
+
+// SetHSVColor changes the channel's color via hue/saturation.
+func (c *LightChannel) SetHSVColor(hue, sat int, effect Effect, duration time.Duration) (string, error) {
+	return c.send("set_hsv", hue, sat, string(effect), int(duration/time.Millisecond))
+}
+
+// SetBrightness changes the channel's brightness.
+func (c *LightChannel) SetBrightness(brightness int, effect Effect, duration time.Duration) (string, error) {
+	return c.send("set_bright", brightness, string(effect), int(duration/time.Millisecond))
+}
+
+// SetColorTemperature changes the channel's color temperature, clamped to
+// the bulb's model CT range.
+func (c *LightChannel) SetColorTemperature(ct int, effect Effect, duration time.Duration) (string, error) {
+	return c.send("set_ct_abx", c.y.clampCT(ct), string(effect), int(duration/time.Millisecond))
+}
+
+// StartColorFlow starts the color flow built by f on the channel.
+func (c *LightChannel) StartColorFlow(f *ColorFlow) error {
+	expr, err := f.expression(c.y.clampCT)
+	if err != nil {
+		return err
+	}
+	_, err = c.send("start_cf", f.Count, int(f.Action), expr)
+	return err
+}
+
+// StopColorFlow stops a running color flow on the channel.
+func (c *LightChannel) StopColorFlow() (string, error) {
+	return c.send("stop_cf")
+}
+
+// SetScene sets the channel directly to the given scene class and params.
+func (c *LightChannel) SetScene(class SceneClass, params ...interface{}) (string, error) {
+	args := append([]interface{}{string(class)}, params...)
+	return c.send("set_scene", args...)
+}
+
+// SetDefault saves the channel's current state as its power-on default.
+func (c *LightChannel) SetDefault() (string, error) {
+	return c.send("set_default")
+}
+
+// Adjust changes brightness, CT or color without needing the current value.
+func (c *LightChannel) Adjust(action AdjustAction, prop AdjustProp) (string, error) {
+	return c.send("set_adjust", string(action), string(prop))
+}
+
+// ToggleDev toggles the main and background light together, on models
+// that support it.
+func (y *Yeelight) ToggleDev() (string, error) {
+	if !y.supports("dev_toggle") {
+		return "", ErrUnsupported
+	}
+	return y.send(y.command("dev_toggle"))
+}
+
+// SetNightLight switches night-light (moonlight) mode on or off via
+// set_ps, on bulbs that advertise support for it.
+func (y *Yeelight) SetNightLight(on bool) (string, error) {
+	if !y.supports("set_ps") {
+		return "", ErrUnsupported
+	}
+
+	state := "off"
+	if on {
+		state = "on"
+	}
+	return y.send(y.command("set_ps", "cfg_night_light", state))
+}
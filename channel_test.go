@@ -0,0 +1,69 @@
+package goyeelight
+
+import "testing"
+
+func TestCapabilityKnownModel(t *testing.T) {
+	y := &Yeelight{Model: "ceiling4"}
+	c := y.capability()
+	if c.CTMin != 2700 || c.CTMax != 6500 || !c.Background || !c.NightLight {
+		t.Errorf("capability = %+v, want the ceiling4 entry", c)
+	}
+}
+
+func TestCapabilityUnknownModelFallsBackToDefault(t *testing.T) {
+	y := &Yeelight{Model: "nonexistent"}
+	c := y.capability()
+	if c.CTMin != defaultCTMin || c.CTMax != defaultCTMax || c.Background || c.NightLight {
+		t.Errorf("capability = %+v, want the default range with no optional features", c)
+	}
+}
+
+func TestClampCT(t *testing.T) {
+	y := &Yeelight{Model: "color"} // CTMin: 1700, CTMax: 6500
+	cases := map[int]int{
+		1000: 1700,
+		4000: 4000,
+		9000: 6500,
+	}
+	for in, want := range cases {
+		if got := y.clampCT(in); got != want {
+			t.Errorf("clampCT(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestSupportsEmptyListIsPermissive(t *testing.T) {
+	y := &Yeelight{}
+	if !y.supports("set_power") {
+		t.Error("supports should be a no-op when Support is empty")
+	}
+}
+
+func TestSupportsChecksDiscoveredList(t *testing.T) {
+	y := &Yeelight{Support: []string{"get_prop", "set_power"}}
+	if !y.supports("set_power") {
+		t.Error("expected set_power to be supported")
+	}
+	if y.supports("set_ct_abx") {
+		t.Error("expected set_ct_abx to be unsupported")
+	}
+}
+
+func TestLightChannelSendRejectsUnsupportedMethod(t *testing.T) {
+	y := &Yeelight{Support: []string{"get_prop"}}
+	c := y.Main()
+	if _, err := c.send("set_power", "on"); err != ErrUnsupported {
+		t.Errorf("send = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestBackgroundChannelPrefix(t *testing.T) {
+	y := &Yeelight{}
+	c := y.Background()
+	if c.prefix != "bg_" {
+		t.Errorf("Background prefix = %q, want %q", c.prefix, "bg_")
+	}
+	if y.Main().prefix != "" {
+		t.Errorf("Main prefix = %q, want empty", y.Main().prefix)
+	}
+}
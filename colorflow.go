@@ -0,0 +1,162 @@
+package goyeelight
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FlowMode is the kind of transition a single ColorFlow step performs.
+type FlowMode int
+
+const (
+	FlowColor FlowMode = 1
+	FlowCT    FlowMode = 2
+	FlowSleep FlowMode = 7
+)
+
+// defaultCTMin/defaultCTMax bound the color temperature accepted by a
+// ColorFlow Temperature transition when the bulb's exact model range is
+// not known.
+const (
+	defaultCTMin = 1700
+	defaultCTMax = 6500
+)
+
+// FlowTransition is a single state change within a color flow.
+type FlowTransition struct {
+	Duration   time.Duration
+	Mode       FlowMode
+	Value      int
+	Brightness int
+}
+
+// ColorFlow builds the count/action/flow-expression triple StartColorFlow
+// sends to the bulb, one transition at a time.
+type ColorFlow struct {
+	Count       int
+	Action      CfAction
+	Transitions []FlowTransition
+}
+
+// RGB appends an RGB color transition.
+func (f *ColorFlow) RGB(d time.Duration, r, g, b, bright int) *ColorFlow {
+	rgb := r<<16 | g<<8 | b
+	f.Transitions = append(f.Transitions, FlowTransition{Duration: d, Mode: FlowColor, Value: rgb, Brightness: bright})
+	return f
+}
+
+// Temperature appends a color temperature transition.
+func (f *ColorFlow) Temperature(d time.Duration, kelvin, bright int) *ColorFlow {
+	f.Transitions = append(f.Transitions, FlowTransition{Duration: d, Mode: FlowCT, Value: kelvin, Brightness: bright})
+	return f
+}
+
+// Sleep appends a transition that pauses for d without changing the
+// bulb's visible state.
+func (f *ColorFlow) Sleep(d time.Duration) *ColorFlow {
+	f.Transitions = append(f.Transitions, FlowTransition{Duration: d, Mode: FlowSleep})
+	return f
+}
+
+// StartColorFlow starts the color flow built by f. It validates each
+// transition's duration and brightness, clamps color temperatures to the
+// range y's model supports, and serializes the transitions as the
+// "dur,mode,value,bright" tuple list the start_cf method expects.
+func (y *Yeelight) StartColorFlow(f *ColorFlow) error {
+	expr, err := f.expression(y.clampCT)
+	if err != nil {
+		return err
+	}
+
+	_, err = y.startCf(f.Count, f.Action, expr)
+	return err
+}
+
+// expression validates and serializes the flow's transitions, clamping
+// color temperature transitions with clampCT.
+func (f *ColorFlow) expression(clampCT func(int) int) (string, error) {
+	if len(f.Transitions) == 0 {
+		return "", errors.New("goyeelight: color flow has no transitions")
+	}
+
+	tuples := make([]string, len(f.Transitions))
+	for i, t := range f.Transitions {
+		if t.Duration < 50*time.Millisecond {
+			return "", fmt.Errorf("goyeelight: transition %d duration %s is below the 50ms minimum", i, t.Duration)
+		}
+		if t.Brightness < -1 || t.Brightness > 100 {
+			return "", fmt.Errorf("goyeelight: transition %d brightness %d out of range [-1,100]", i, t.Brightness)
+		}
+
+		value := t.Value
+		if t.Mode == FlowCT {
+			value = clampCT(value)
+		}
+
+		tuples[i] = fmt.Sprintf("%d,%d,%d,%d", t.Duration/time.Millisecond, t.Mode, value, t.Brightness)
+	}
+
+	return strings.Join(tuples, ","), nil
+}
+
+// PresetSunrise mimics the recommended sunrise scene: a slow warm-up
+// through color temperature and brightness, then holds.
+func PresetSunrise() *ColorFlow {
+	f := &ColorFlow{Action: CfActionStay}
+	f.Temperature(50*time.Millisecond, 1700, 1)
+	f.Temperature(360000*time.Millisecond, 1700, 10)
+	f.Temperature(540000*time.Millisecond, 2700, 100)
+	f.Temperature(600000*time.Millisecond, 5000, 100)
+	f.Count = len(f.Transitions)
+	return f
+}
+
+// PresetSunset mimics the recommended sunset scene: a slow cool-down
+// through color temperature and brightness, ending powered off.
+func PresetSunset() *ColorFlow {
+	f := &ColorFlow{Action: CfActionOff}
+	f.Temperature(50*time.Millisecond, 2700, 10)
+	f.Temperature(180000*time.Millisecond, 2700, 5)
+	f.Temperature(600000*time.Millisecond, 1700, 1)
+	f.Count = len(f.Transitions)
+	return f
+}
+
+// PresetDisco cycles through saturated colors at the given tempo, in
+// beats per minute (defaults to 120 if bpm <= 0).
+func PresetDisco(bpm int) *ColorFlow {
+	if bpm <= 0 {
+		bpm = 120
+	}
+	beat := time.Minute / time.Duration(bpm)
+
+	colors := [][3]int{
+		{255, 0, 0}, {0, 255, 0}, {0, 0, 255},
+		{255, 255, 0}, {0, 255, 255}, {255, 0, 255},
+	}
+
+	f := &ColorFlow{Count: 0, Action: CfActionRecover}
+	for _, c := range colors {
+		f.RGB(beat, c[0], c[1], c[2], 100)
+	}
+	return f
+}
+
+// PresetPulse blinks count times between the given color and a dim level
+// of the same color.
+func PresetPulse(r, g, b, count int) *ColorFlow {
+	f := &ColorFlow{Count: count * 2, Action: CfActionRecover}
+	f.RGB(250*time.Millisecond, r, g, b, 100)
+	f.RGB(250*time.Millisecond, r, g, b, 1)
+	return f
+}
+
+// PresetStrobe flashes white rapidly until stopped.
+func PresetStrobe() *ColorFlow {
+	f := &ColorFlow{Count: 0, Action: CfActionRecover}
+	f.RGB(50*time.Millisecond, 255, 255, 255, 100)
+	f.Sleep(50 * time.Millisecond)
+	return f
+}
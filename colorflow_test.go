@@ -0,0 +1,64 @@
+package goyeelight
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func identityClamp(kelvin int) int { return kelvin }
+
+func TestColorFlowExpression(t *testing.T) {
+	f := &ColorFlow{}
+	f.RGB(1000*time.Millisecond, 255, 0, 0, 50)
+	f.Temperature(500*time.Millisecond, 4000, 100)
+	f.Sleep(200 * time.Millisecond)
+
+	expr, err := f.expression(identityClamp)
+	if err != nil {
+		t.Fatalf("expression: %v", err)
+	}
+
+	want := "1000,1,16711680,50,500,2,4000,100,200,7,0,0"
+	if expr != want {
+		t.Errorf("expression = %q, want %q", expr, want)
+	}
+}
+
+func TestColorFlowExpressionNoTransitions(t *testing.T) {
+	f := &ColorFlow{}
+	if _, err := f.expression(identityClamp); err == nil {
+		t.Error("expected an error for a flow with no transitions")
+	}
+}
+
+func TestColorFlowExpressionRejectsShortDuration(t *testing.T) {
+	f := &ColorFlow{}
+	f.RGB(10*time.Millisecond, 0, 0, 0, 0)
+
+	if _, err := f.expression(identityClamp); err == nil {
+		t.Error("expected an error for a duration below 50ms")
+	}
+}
+
+func TestColorFlowExpressionRejectsBadBrightness(t *testing.T) {
+	f := &ColorFlow{}
+	f.RGB(100*time.Millisecond, 0, 0, 0, 101)
+
+	if _, err := f.expression(identityClamp); err == nil {
+		t.Error("expected an error for brightness above 100")
+	}
+}
+
+func TestColorFlowExpressionClampsTemperature(t *testing.T) {
+	f := &ColorFlow{}
+	f.Temperature(100*time.Millisecond, 9000, 100)
+
+	expr, err := f.expression(func(int) int { return 6500 })
+	if err != nil {
+		t.Fatalf("expression: %v", err)
+	}
+	if !strings.Contains(expr, ",6500,") {
+		t.Errorf("expression = %q, want the clamped value 6500", expr)
+	}
+}
@@ -0,0 +1,36 @@
+package goyeelight
+
+import "encoding/json"
+
+// Command is the typed envelope every request to the bulb is marshaled
+// through via encoding/json, replacing the previous ad-hoc string
+// concatenation (which broke on values containing a `"`).
+type Command struct {
+	ID     int           `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// Response is the typed envelope a reply is decoded into before handing
+// the payload (or error) back to the caller.
+type Response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// command builds a Command for method/params, assigning it a fresh
+// monotonic id.
+func (y *Yeelight) command(method string, params ...interface{}) Command {
+	return Command{ID: y.nextID(), Method: method, Params: params}
+}
+
+// send marshals cmd and sends it through request, returning the decoded
+// result payload.
+func (y *Yeelight) send(cmd Command) (string, error) {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return "", err
+	}
+	return y.request(cmd.ID, string(data))
+}
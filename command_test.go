@@ -0,0 +1,50 @@
+package goyeelight
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCommandMarshal(t *testing.T) {
+	cmd := Command{ID: 7, Method: "set_name", Params: []interface{}{`a "quoted" name`}}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got Command
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("round-trip Unmarshal: %v", err)
+	}
+
+	if got.ID != cmd.ID || got.Method != cmd.Method {
+		t.Errorf("got %+v, want %+v", got, cmd)
+	}
+	if len(got.Params) != 1 || got.Params[0] != `a "quoted" name` {
+		t.Errorf("Params = %v, want the quoted name preserved intact", got.Params)
+	}
+}
+
+func TestResponseUnmarshalOk(t *testing.T) {
+	var res Response
+	if err := json.Unmarshal([]byte(`{"id":1,"result":["on"]}`), &res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if res.Error != nil {
+		t.Errorf("Error = %+v, want nil", res.Error)
+	}
+	if string(res.Result) != `["on"]` {
+		t.Errorf("Result = %s", res.Result)
+	}
+}
+
+func TestResponseUnmarshalError(t *testing.T) {
+	var res Response
+	if err := json.Unmarshal([]byte(`{"id":1,"error":{"code":-1,"message":"invalid params"}}`), &res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if res.Error == nil || res.Error.Message != "invalid params" {
+		t.Errorf("Error = %+v, want message %q", res.Error, "invalid params")
+	}
+}
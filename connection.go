@@ -0,0 +1,325 @@
+package goyeelight
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrDisconnected is returned to callers with an outstanding request when
+// the persistent connection opened by Connect is lost before a reply
+// arrives.
+var ErrDisconnected = errors.New("goyeelight: disconnected")
+
+// keepAliveInterval is how often a persistent connection pings the bulb
+// with a get_prop request to detect a silently dropped socket.
+const keepAliveInterval = 60 * time.Second
+
+// PropsEvent is a "props" notification pushed by the bulb whenever one of
+// its properties changes on its own, e.g. from a physical switch or
+// another controller. Only the properties that actually changed in this
+// event are non-nil.
+type PropsEvent struct {
+	Power     *string
+	Bright    *int
+	CT        *int
+	RGB       *int
+	Hue       *int
+	Sat       *int
+	ColorMode *int
+	Flowing   *int
+	DelayOff  *int
+	Name      *string
+}
+
+// callResult is what a pending request is resolved with once its reply
+// line arrives, or ErrDisconnected if the connection drops first.
+type callResult struct {
+	data []byte
+	err  error
+}
+
+// Connect opens a persistent TCP connection to the bulb and starts a
+// reader goroutine that demultiplexes inbound JSON lines by id to the
+// matching in-flight request, routing id-less "props" notifications to
+// the channel returned by Notifications. If the connection drops, it is
+// redialed with exponential backoff until ctx is done or Close is called;
+// requests in flight at the time of the drop fail with ErrDisconnected.
+//
+// Once connected, the existing per-method calls (SetRGB, GetProp, ...)
+// transparently dispatch over this connection instead of dialing once per
+// call.
+func (y *Yeelight) Connect(ctx context.Context) error {
+	y.mu.Lock()
+	if y.netConn != nil {
+		y.mu.Unlock()
+		return nil
+	}
+
+	connCtx, cancel := context.WithCancel(ctx)
+	y.cancel = cancel
+	y.pending = make(map[int]chan callResult)
+	y.notify = make(chan PropsEvent, 16)
+	y.mu.Unlock()
+
+	nc, err := net.DialTimeout("tcp", y.host+":"+y.port, timeout)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	y.mu.Lock()
+	y.netConn = nc
+	y.mu.Unlock()
+
+	go y.serve(connCtx, nc)
+	go y.keepalive(connCtx)
+
+	return nil
+}
+
+// Close tears down the persistent connection opened by Connect, if any.
+func (y *Yeelight) Close() error {
+	y.mu.Lock()
+	cancel := y.cancel
+	nc := y.netConn
+	y.cancel = nil
+	y.netConn = nil
+	y.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if nc != nil {
+		return nc.Close()
+	}
+	return nil
+}
+
+// Notifications returns the channel on which props notifications pushed
+// by the bulb are delivered. It is only populated once Connect has been
+// called; callers that never Connect get a nil channel, which blocks
+// forever in a select, as intended.
+func (y *Yeelight) Notifications() <-chan PropsEvent {
+	y.mu.Lock()
+	defer y.mu.Unlock()
+	return y.notify
+}
+
+// serve owns a single dialed connection: it reads replies and
+// notifications until the connection breaks, fails every pending request
+// with ErrDisconnected, then redials with exponential backoff until ctx
+// is done.
+func (y *Yeelight) serve(ctx context.Context, nc net.Conn) {
+	backoff := time.Second
+	for {
+		y.readLoop(ctx, nc)
+
+		y.mu.Lock()
+		for id, ch := range y.pending {
+			ch <- callResult{err: ErrDisconnected}
+			close(ch)
+			delete(y.pending, id)
+		}
+		y.netConn = nil
+		y.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		var err error
+		for {
+			nc, err = net.DialTimeout("tcp", y.host+":"+y.port, timeout)
+			if err == nil {
+				break
+			}
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+		backoff = time.Second
+
+		y.mu.Lock()
+		y.netConn = nc
+		y.mu.Unlock()
+	}
+}
+
+// readLoop reads newline-delimited JSON from nc until it errors, routing
+// each line either to its pending request (by id) or to the notification
+// channel (no id).
+func (y *Yeelight) readLoop(ctx context.Context, nc net.Conn) {
+	r := bufio.NewReader(nc)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var head struct {
+			ID *int `json:"id"`
+		}
+		if err := json.Unmarshal(line, &head); err != nil {
+			continue
+		}
+
+		if head.ID == nil {
+			y.dispatchNotification(line)
+			continue
+		}
+
+		y.mu.Lock()
+		ch, ok := y.pending[*head.ID]
+		if ok {
+			delete(y.pending, *head.ID)
+		}
+		y.mu.Unlock()
+
+		if ok {
+			ch <- callResult{data: append([]byte(nil), line...)}
+			close(ch)
+		}
+	}
+}
+
+// dispatchNotification parses a "props" notification line and delivers
+// it on the Notifications channel, dropping it if nobody is listening.
+func (y *Yeelight) dispatchNotification(line []byte) {
+	var msg struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(line, &msg); err != nil || msg.Method != "props" {
+		return
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Params, &raw); err != nil {
+		return
+	}
+
+	ev := PropsEvent{
+		Power:     unmarshalString(raw, "power"),
+		Bright:    unmarshalInt(raw, "bright"),
+		CT:        unmarshalInt(raw, "ct"),
+		RGB:       unmarshalInt(raw, "rgb"),
+		Hue:       unmarshalInt(raw, "hue"),
+		Sat:       unmarshalInt(raw, "sat"),
+		ColorMode: unmarshalInt(raw, "color_mode"),
+		Flowing:   unmarshalInt(raw, "flowing"),
+		DelayOff:  unmarshalInt(raw, "delayoff"),
+		Name:      unmarshalString(raw, "name"),
+	}
+
+	y.mu.Lock()
+	ch := y.notify
+	y.mu.Unlock()
+	if ch == nil {
+		return
+	}
+
+	select {
+	case ch <- ev:
+	default:
+	}
+}
+
+// unmarshalString decodes raw[key] as a string, returning nil if key is
+// absent or does not decode as one.
+func unmarshalString(raw map[string]json.RawMessage, key string) *string {
+	v, ok := raw[key]
+	if !ok {
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(v, &value); err != nil {
+		return nil
+	}
+	return &value
+}
+
+// unmarshalInt decodes raw[key] as an int, returning nil if key is absent
+// or does not decode as one.
+func unmarshalInt(raw map[string]json.RawMessage, key string) *int {
+	v, ok := raw[key]
+	if !ok {
+		return nil
+	}
+	var value int
+	if err := json.Unmarshal(v, &value); err != nil {
+		return nil
+	}
+	return &value
+}
+
+// keepalive periodically pings the bulb with a get_prop request so a
+// silently dropped socket is noticed and redialed by serve.
+func (y *Yeelight) keepalive(ctx context.Context) {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			y.mu.Lock()
+			connected := y.netConn != nil
+			y.mu.Unlock()
+			if connected {
+				y.GetProp("power")
+			}
+		}
+	}
+}
+
+// requestPersistent sends cmd over the active persistent connection and
+// waits for the reply matching id, for the connection to drop, or for
+// timeout to elapse without a reply.
+func (y *Yeelight) requestPersistent(id int, cmd string) (string, error) {
+	ch := make(chan callResult, 1)
+
+	y.mu.Lock()
+	nc := y.netConn
+	if nc == nil {
+		y.mu.Unlock()
+		return "", ErrDisconnected
+	}
+	y.pending[id] = ch
+	y.mu.Unlock()
+
+	if _, err := fmt.Fprint(nc, cmd+"\r\n"); err != nil {
+		y.mu.Lock()
+		delete(y.pending, id)
+		y.mu.Unlock()
+		return "", ErrDisconnected
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-ch:
+		if res.err != nil {
+			return "", res.err
+		}
+		return response(string(res.data))
+	case <-timer.C:
+		y.mu.Lock()
+		delete(y.pending, id)
+		y.mu.Unlock()
+		return "", fmt.Errorf("goyeelight: timed out waiting for reply to request %d", id)
+	}
+}
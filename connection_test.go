@@ -0,0 +1,81 @@
+package goyeelight
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUnmarshalStringPresent(t *testing.T) {
+	raw := map[string]json.RawMessage{"name": json.RawMessage(`"bedroom"`)}
+	got := unmarshalString(raw, "name")
+	if got == nil || *got != "bedroom" {
+		t.Errorf("unmarshalString = %v, want \"bedroom\"", got)
+	}
+}
+
+func TestUnmarshalStringAbsentOrWrongType(t *testing.T) {
+	raw := map[string]json.RawMessage{"bright": json.RawMessage(`100`)}
+	if got := unmarshalString(raw, "bright"); got != nil {
+		t.Errorf("unmarshalString = %v, want nil for a non-string value", got)
+	}
+	if got := unmarshalString(raw, "missing"); got != nil {
+		t.Errorf("unmarshalString = %v, want nil for an absent key", got)
+	}
+}
+
+func TestUnmarshalIntPresent(t *testing.T) {
+	raw := map[string]json.RawMessage{"bright": json.RawMessage(`100`)}
+	got := unmarshalInt(raw, "bright")
+	if got == nil || *got != 100 {
+		t.Errorf("unmarshalInt = %v, want 100", got)
+	}
+}
+
+func TestUnmarshalIntAbsentOrWrongType(t *testing.T) {
+	raw := map[string]json.RawMessage{"name": json.RawMessage(`"bedroom"`)}
+	if got := unmarshalInt(raw, "name"); got != nil {
+		t.Errorf("unmarshalInt = %v, want nil for a non-int value", got)
+	}
+	if got := unmarshalInt(raw, "missing"); got != nil {
+		t.Errorf("unmarshalInt = %v, want nil for an absent key", got)
+	}
+}
+
+func TestDispatchNotificationDeliversPropsEvent(t *testing.T) {
+	y := &Yeelight{notify: make(chan PropsEvent, 1)}
+	line := []byte(`{"method":"props","params":{"power":"on","bright":80}}` + "\n")
+
+	y.dispatchNotification(line)
+
+	select {
+	case ev := <-y.notify:
+		if ev.Power == nil || *ev.Power != "on" {
+			t.Errorf("Power = %v, want \"on\"", ev.Power)
+		}
+		if ev.Bright == nil || *ev.Bright != 80 {
+			t.Errorf("Bright = %v, want 80", ev.Bright)
+		}
+	default:
+		t.Fatal("expected a PropsEvent to be delivered")
+	}
+}
+
+func TestDispatchNotificationIgnoresOtherMethods(t *testing.T) {
+	y := &Yeelight{notify: make(chan PropsEvent, 1)}
+	line := []byte(`{"method":"other","params":{}}` + "\n")
+
+	y.dispatchNotification(line)
+
+	select {
+	case ev := <-y.notify:
+		t.Errorf("expected no event, got %+v", ev)
+	default:
+	}
+}
+
+func TestDispatchNotificationDropsWhenNobodyListening(t *testing.T) {
+	y := &Yeelight{}
+	line := []byte(`{"method":"props","params":{"power":"on"}}` + "\n")
+
+	y.dispatchNotification(line) // must not panic or block
+}
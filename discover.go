@@ -0,0 +1,222 @@
+package goyeelight
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// discoveryAddr is the Yeelight SSDP multicast group and port.
+	discoveryAddr = "239.255.255.250:1982"
+
+	// discoveryMsg is the SSDP M-SEARCH request used to trigger a response
+	// from every wifi_bulb on the LAN.
+	discoveryMsg = "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: 239.255.255.250:1982\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"ST: wifi_bulb\r\n" +
+		"\r\n"
+)
+
+// Discover sends an SSDP M-SEARCH broadcast and collects the bulbs that
+// respond within timeout. Devices are deduplicated by their id, and the
+// returned instances are pre-populated with the properties advertised in
+// the response so callers can immediately invoke methods such as GetProp
+// or SetRGB on them.
+func Discover(timeout time.Duration) ([]*Yeelight, error) {
+	groupAddr, err := net.ResolveUDPAddr("udp4", discoveryAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unconnected socket is used because bulbs reply from their own
+	// unicast address, not from the multicast group address.
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.WriteToUDP([]byte(discoveryMsg), groupAddr); err != nil {
+		return nil, err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	seen := make(map[string]*Yeelight)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+
+		y := parseSSDPResponse(string(buf[:n]))
+		if y == nil || y.ID == "" {
+			continue
+		}
+		seen[y.ID] = y
+	}
+
+	devices := make([]*Yeelight, 0, len(seen))
+	for _, y := range seen {
+		devices = append(devices, y)
+	}
+	return devices, nil
+}
+
+// discoverAsyncInterval is how often DiscoverAsync repeats the M-SEARCH
+// broadcast while ctx remains open.
+const discoverAsyncInterval = 5 * time.Second
+
+// DiscoverAsync repeats the M-SEARCH discovery on a fixed interval and
+// streams newly found bulbs on the returned channel, deduplicated by id
+// for the lifetime of the call. The channel is closed when ctx is done.
+func DiscoverAsync(ctx context.Context) <-chan *Yeelight {
+	out := make(chan *Yeelight)
+
+	go func() {
+		defer close(out)
+
+		seen := make(map[string]struct{})
+		ticker := time.NewTicker(discoverAsyncInterval)
+		defer ticker.Stop()
+
+		emit := func() {
+			devices, err := Discover(discoverAsyncInterval)
+			if err != nil {
+				return
+			}
+			for _, y := range devices {
+				if _, ok := seen[y.ID]; ok {
+					continue
+				}
+				seen[y.ID] = struct{}{}
+				select {
+				case out <- y:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		emit()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				emit()
+			}
+		}
+	}()
+
+	return out
+}
+
+// Listen joins the Yeelight multicast group passively and emits a
+// *Yeelight for every advertisement a bulb broadcasts on its own (for
+// example on boot or state change), without sending an M-SEARCH request.
+// The returned channel is closed when ctx is done or the socket errors.
+func Listen(ctx context.Context) (<-chan *Yeelight, error) {
+	groupAddr, err := net.ResolveUDPAddr("udp4", discoveryAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", nil, groupAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *Yeelight)
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go func() {
+		defer close(out)
+		defer conn.Close()
+
+		buf := make([]byte, 2048)
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+
+			y := parseSSDPResponse(string(buf[:n]))
+			if y == nil || y.ID == "" {
+				continue
+			}
+
+			select {
+			case out <- y:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// parseSSDPResponse parses a raw SSDP response/advertisement from a
+// Yeelight bulb into a Yeelight instance, pre-populated with the
+// properties the bulb advertised. It returns nil if the payload does not
+// contain a usable Location header.
+func parseSSDPResponse(data string) *Yeelight {
+	host, port := "", ""
+	y := &Yeelight{}
+
+	for _, line := range strings.Split(data, "\r\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "location":
+			loc := strings.TrimPrefix(value, "yeelight://")
+			if h, p, err := net.SplitHostPort(loc); err == nil {
+				host, port = h, p
+			}
+		case "id":
+			y.ID = value
+		case "model":
+			y.Model = value
+		case "fw_ver":
+			y.FwVer = value
+		case "support":
+			y.Support = strings.Fields(value)
+		case "power":
+			y.Power = value
+		case "bright":
+			y.Bright = value
+		case "color_mode":
+			y.ColorMode = value
+		case "ct":
+			y.CT = value
+		case "rgb":
+			y.RGB = value
+		case "hue":
+			y.Hue = value
+		case "sat":
+			y.Sat = value
+		case "name":
+			y.Name = value
+		}
+	}
+
+	if host == "" {
+		return nil
+	}
+
+	y.host, y.port = host, port
+	return y
+}
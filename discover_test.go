@@ -0,0 +1,53 @@
+package goyeelight
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSSDPResponse(t *testing.T) {
+	data := "HTTP/1.1 200 OK\r\n" +
+		"Cache-Control: max-age=3600\r\n" +
+		"Location: yeelight://192.168.1.10:55443\r\n" +
+		"id: 0x0000000001234567\r\n" +
+		"model: color\r\n" +
+		"fw_ver: 18\r\n" +
+		"support: get_prop set_power set_rgb\r\n" +
+		"power: on\r\n" +
+		"bright: 100\r\n" +
+		"color_mode: 2\r\n" +
+		"ct: 4000\r\n" +
+		"rgb: 16711680\r\n" +
+		"hue: 0\r\n" +
+		"sat: 100\r\n" +
+		"name: bedroom\r\n" +
+		"\r\n"
+
+	y := parseSSDPResponse(data)
+	if y == nil {
+		t.Fatal("expected a non-nil Yeelight")
+	}
+
+	if y.host != "192.168.1.10" || y.port != "55443" {
+		t.Errorf("host/port = %q/%q, want 192.168.1.10/55443", y.host, y.port)
+	}
+	if y.ID != "0x0000000001234567" {
+		t.Errorf("ID = %q", y.ID)
+	}
+	if y.Model != "color" {
+		t.Errorf("Model = %q", y.Model)
+	}
+	if !reflect.DeepEqual(y.Support, []string{"get_prop", "set_power", "set_rgb"}) {
+		t.Errorf("Support = %v", y.Support)
+	}
+	if y.Power != "on" || y.Bright != "100" || y.Name != "bedroom" {
+		t.Errorf("Power/Bright/Name = %q/%q/%q", y.Power, y.Bright, y.Name)
+	}
+}
+
+func TestParseSSDPResponseWithoutLocation(t *testing.T) {
+	data := "NOTIFY * HTTP/1.1\r\nid: 1\r\n\r\n"
+	if y := parseSSDPResponse(data); y != nil {
+		t.Errorf("expected nil without a Location header, got %+v", y)
+	}
+}
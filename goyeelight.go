@@ -3,10 +3,14 @@ package goyeelight
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image/color"
 	"net"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -16,6 +20,33 @@ const timeout = time.Duration(10 * time.Second)
 // Create an instance of Yeelight, by using New()
 type Yeelight struct {
 	host, port string
+
+	// The fields below are pre-populated when the instance comes from
+	// Discover, DiscoverAsync or Listen; they are left zero when created
+	// through New.
+	ID        string
+	Model     string
+	FwVer     string
+	Support   []string
+	Power     string
+	Bright    string
+	ColorMode string
+	CT        string
+	RGB       string
+	Hue       string
+	Sat       string
+	Name      string
+
+	// mu guards the fields below: the request id counter, the persistent
+	// connection managed by Connect/Close, and the music-mode connection
+	// opened by StartMusic.
+	mu        sync.Mutex
+	netConn   net.Conn
+	lastID    int
+	pending   map[int]chan callResult
+	notify    chan PropsEvent
+	cancel    context.CancelFunc
+	musicConn net.Conn
 }
 
 type (
@@ -25,27 +56,43 @@ type (
 		Data   json.RawMessage `json:"data"`
 	}
 
-	// ResponseOk struct is used on the success responses
-	ResponseOk struct {
-		ID     int             `json:"id"`
-		Result json.RawMessage `json:"result"`
-	}
-
-	// ResponseError struct is used on the error responses
-	ResponseError struct {
-		ID    int   `json:"id"`
-		Error Error `json:"error"`
-	}
-
-	// Error struct is used on the ResponseError payload
+	// Error struct is used on the Response payload
 	Error struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
 	}
 )
 
-// Makes the request
-func (y *Yeelight) request(cmd string) (string, error) {
+// nextID returns the next monotonic request id for this instance.
+func (y *Yeelight) nextID() int {
+	y.mu.Lock()
+	defer y.mu.Unlock()
+	y.lastID++
+	return y.lastID
+}
+
+// Makes the request, dispatching over the music-mode connection opened by
+// StartMusic when one is active, else over the persistent connection
+// opened by Connect when one is active, falling back to a dial-per-call
+// otherwise.
+func (y *Yeelight) request(id int, cmd string) (string, error) {
+	y.mu.Lock()
+	music := y.musicConn
+	nc := y.netConn
+	y.mu.Unlock()
+
+	if music != nil {
+		return y.requestMusic(music, cmd)
+	}
+	if nc != nil {
+		return y.requestPersistent(id, cmd)
+	}
+	return y.requestDial(cmd)
+}
+
+// requestDial opens a fresh TCP connection for a single command, as the
+// Yeelight protocol does not require a persistent session.
+func (y *Yeelight) requestDial(cmd string) (string, error) {
 	conn, err := net.DialTimeout("tcp", y.host+":"+y.port, timeout)
 	if err != nil {
 		return "", err
@@ -64,19 +111,15 @@ func (y *Yeelight) request(cmd string) (string, error) {
 
 // Handles the response
 func response(data string) (string, error) {
-	res := ResponseOk{}
-	json.Unmarshal([]byte(data), &res)
-
-	if res.Result == nil {
-		// error
-		res := ResponseError{}
-		json.Unmarshal([]byte(data), &res)
-
-		err := errors.New(res.Error.Message)
+	res := Response{}
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
 		return "", err
 	}
 
-	// okay
+	if res.Error != nil {
+		return "", errors.New(res.Error.Message)
+	}
+
 	return string(res.Result), nil
 }
 
@@ -88,13 +131,12 @@ func New(host, port string) *Yeelight {
 
 // GetProp method is used to retrieve current property of smart LED.
 func (y *Yeelight) GetProp(values ...string) (map[string]string, error) {
-	cmd := `{"id":1,"method":"get_prop","params":[`
-	for _, value := range values {
-		cmd += `"` + string(value) + `",`
+	params := make([]interface{}, len(values))
+	for i, value := range values {
+		params[i] = value
 	}
-	cmd += `]}`
 
-	res, err := y.request(cmd)
+	res, err := y.send(y.command("get_prop", params...))
 	if err != nil {
 		return nil, err
 	}
@@ -115,50 +157,134 @@ func (y *Yeelight) GetProp(values ...string) (map[string]string, error) {
 	return m, nil
 }
 
+// SetColorTemperature method is used to change the color temperature of a
+// smart LED.
+func (y *Yeelight) SetColorTemperature(ct int, effect Effect, duration time.Duration) (string, error) {
+	return y.send(y.command("set_ct_abx", ct, string(effect), int(duration/time.Millisecond)))
+}
+
 // SetCtAbx method is used to change the color temperature of a smart LED.
+//
+// Deprecated: use SetColorTemperature instead.
 func (y *Yeelight) SetCtAbx(value, effect, duration string) (string, error) {
-	cmd := `{"id":2,"method":"set_ct_abx","params":[` + value + `,"` + effect + `",` + duration + `]}`
-	return y.request(cmd)
+	ct, err := strconv.Atoi(value)
+	if err != nil {
+		return "", err
+	}
+	ms, err := strconv.Atoi(duration)
+	if err != nil {
+		return "", err
+	}
+	return y.SetColorTemperature(ct, Effect(effect), time.Duration(ms)*time.Millisecond)
+}
+
+// SetColor method is used to change the color RGB of a smart LED.
+func (y *Yeelight) SetColor(c color.RGBA, effect Effect, duration time.Duration) (string, error) {
+	rgb := int(c.R)<<16 | int(c.G)<<8 | int(c.B)
+	return y.send(y.command("set_rgb", rgb, string(effect), int(duration/time.Millisecond)))
 }
 
 // SetRGB method is used to change the color RGB of a smart LED.
+//
+// Deprecated: use SetColor instead.
 func (y *Yeelight) SetRGB(value, effect, duration string) (string, error) {
-	cmd := `{"id":3,"method":"set_rgb","params":[` + value + `,"` + effect + `",` + duration + `]}`
-	return y.request(cmd)
+	rgb, err := strconv.Atoi(value)
+	if err != nil {
+		return "", err
+	}
+	ms, err := strconv.Atoi(duration)
+	if err != nil {
+		return "", err
+	}
+	c := color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb)}
+	return y.SetColor(c, Effect(effect), time.Duration(ms)*time.Millisecond)
+}
+
+// SetHSVColor method is used to change the color of a smart LED using hue
+// and saturation.
+func (y *Yeelight) SetHSVColor(hue, sat int, effect Effect, duration time.Duration) (string, error) {
+	return y.send(y.command("set_hsv", hue, sat, string(effect), int(duration/time.Millisecond)))
 }
 
 // SetHSV method is used to change the color of a smart LED.
+//
+// Deprecated: use SetHSVColor instead.
 func (y *Yeelight) SetHSV(hue, sat, effect, duration string) (string, error) {
-	cmd := `{"id":4,"method":"set_hsv","params":[` + hue + `,` + sat + `,"` + effect + `",` + duration + `]}`
-	return y.request(cmd)
+	h, err := strconv.Atoi(hue)
+	if err != nil {
+		return "", err
+	}
+	s, err := strconv.Atoi(sat)
+	if err != nil {
+		return "", err
+	}
+	ms, err := strconv.Atoi(duration)
+	if err != nil {
+		return "", err
+	}
+	return y.SetHSVColor(h, s, Effect(effect), time.Duration(ms)*time.Millisecond)
+}
+
+// SetBrightness method is used to change the brightness of a smart LED.
+func (y *Yeelight) SetBrightness(brightness int, effect Effect, duration time.Duration) (string, error) {
+	return y.send(y.command("set_bright", brightness, string(effect), int(duration/time.Millisecond)))
 }
 
 // SetBright method is used to change the brightness of a smart LED.
+//
+// Deprecated: use SetBrightness instead.
 func (y *Yeelight) SetBright(brightness, effect, duration string) (string, error) {
-	cmd := `{"id":5,"method":"set_bright","params":[` + brightness + `,"` + effect + `",` + duration + `]}`
-	return y.request(cmd)
+	b, err := strconv.Atoi(brightness)
+	if err != nil {
+		return "", err
+	}
+	ms, err := strconv.Atoi(duration)
+	if err != nil {
+		return "", err
+	}
+	return y.SetBrightness(b, Effect(effect), time.Duration(ms)*time.Millisecond)
+}
+
+// SetPowerState method is used to switch on or off the smart LED (software managed on/off).
+func (y *Yeelight) SetPowerState(on bool, effect Effect, duration time.Duration) (string, error) {
+	power := "off"
+	if on {
+		power = "on"
+	}
+	return y.send(y.command("set_power", power, string(effect), int(duration/time.Millisecond)))
 }
 
 // SetPower method is used to switch on or off the smart LED (software managed on/off).
+//
+// Deprecated: use SetPowerState instead.
 func (y *Yeelight) SetPower(power, effect, duration string) (string, error) {
-	cmd := `{"id":6,"method":"set_power","params":["` + power + `","` + effect + `",` + duration + `]}`
-	return y.request(cmd)
+	ms, err := strconv.Atoi(duration)
+	if err != nil {
+		return "", err
+	}
+	return y.SetPowerState(power == "on", Effect(effect), time.Duration(ms)*time.Millisecond)
 }
 
 // Toogle method is used to toggle the smart LED.
 // Note: This method is defined because sometimes user may just want
 // to flip the state without knowing the current state.
 func (y *Yeelight) Toogle() (string, error) {
-	cmd := `{"id":7,"method":"toggle","params":[]}`
-	return y.request(cmd)
+	return y.send(y.command("toggle"))
 }
 
 // SetDefault method is used to save current state of smart LED in persistent
 // memory. So if user powers off and then powers on the smart LED again (hard power reset),
 // the smart LED will show last saved state.
 func (y *Yeelight) SetDefault() (string, error) {
-	cmd := `{"id":8,"method":"set_default","params":[]}`
-	return y.request(cmd)
+	return y.send(y.command("set_default"))
+}
+
+// startCf sends a start_cf command built from native types: count is the
+// number of state changes to run before action takes effect (0 means
+// infinite), and flowExpression is a comma-joined "dur,mode,value,bright"
+// tuple list.
+func (y *Yeelight) startCf(count int, action CfAction, flowExpression string) (string, error) {
+	return y.send(y.command("start_cf", count, int(action), flowExpression))
 }
 
 // StartCf method is used to start a color flow. Color flow is a series of smart
@@ -166,57 +292,146 @@ func (y *Yeelight) SetDefault() (string, error) {
 // temperature changing.This is the most powerful command. All our recommended scenes,
 // e.g. Sunrise/Sunset effect is implemented using this method. With the flow expression, user
 // can actually “program” the light effect.
+//
+// Deprecated: use StartColorFlow instead.
 func (y *Yeelight) StartCf(count, action, flowExpression string) (string, error) {
-	cmd := `{"id":9,"method":"start_cf","params":[` + count + `,` + action + `,"` + flowExpression + `"]}`
-	return y.request(cmd)
+	c, err := strconv.Atoi(count)
+	if err != nil {
+		return "", err
+	}
+	a, err := strconv.Atoi(action)
+	if err != nil {
+		return "", err
+	}
+	return y.startCf(c, CfAction(a), flowExpression)
 }
 
 // StopCf method is used to stop a running color flow.
 func (y *Yeelight) StopCf() (string, error) {
-	cmd := `{"id":10,"method":"stop_cf","params":[]}`
-	return y.request(cmd)
+	return y.send(y.command("stop_cf"))
+}
+
+// setScene sends a typed set_scene command for class.
+func (y *Yeelight) setScene(class SceneClass, params ...interface{}) (string, error) {
+	args := append([]interface{}{string(class)}, params...)
+	return y.send(y.command("set_scene", args...))
+}
+
+// SetColorScene turns the LED on directly into the given RGB color and brightness.
+func (y *Yeelight) SetColorScene(c color.RGBA, bright int) (string, error) {
+	rgb := int(c.R)<<16 | int(c.G)<<8 | int(c.B)
+	return y.setScene(SceneColor, rgb, bright)
+}
+
+// SetHSVScene turns the LED on directly into the given hue/saturation and brightness.
+func (y *Yeelight) SetHSVScene(hue, sat, bright int) (string, error) {
+	return y.setScene(SceneHSV, hue, sat, bright)
+}
+
+// SetCTScene turns the LED on directly into the given color temperature and brightness.
+func (y *Yeelight) SetCTScene(ct, bright int) (string, error) {
+	return y.setScene(SceneCT, ct, bright)
+}
+
+// SetAutoDelayOffScene turns the LED on at bright and automatically
+// powers it off again after duration.
+func (y *Yeelight) SetAutoDelayOffScene(bright int, duration time.Duration) (string, error) {
+	return y.setScene(SceneAutoDelayOff, bright, int(duration/time.Minute))
 }
 
 // SetScene method is used to set the smart LED directly to specified state.
 // If the smart LED is off, then it will turn on the smart LED firstly and then
 // apply the specified command.
+//
+// Deprecated: use the typed SetColorScene, SetHSVScene, SetCTScene or
+// SetAutoDelayOffScene instead.
 func (y *Yeelight) SetScene(class, values string) (string, error) {
-	cmd := `{"id":11,"method":"set_scene","params":["` + class + `",` + values + `]}`
-	fmt.Println(cmd)
-	return y.request(cmd)
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte("["+values+"]"), &raw); err != nil {
+		return "", err
+	}
+
+	params := make([]interface{}, 0, len(raw)+1)
+	params = append(params, class)
+	for _, v := range raw {
+		params = append(params, v)
+	}
+	return y.send(y.command("set_scene", params...))
+}
+
+// CronAddAfter method is used to start a timer job on the smart LED that
+// fires after the given duration. cronType 0 (power off) is the only job
+// type the protocol currently supports.
+func (y *Yeelight) CronAddAfter(cronType int, after time.Duration) (string, error) {
+	return y.send(y.command("cron_add", cronType, int(after/time.Minute)))
 }
 
 // CronAdd method is used to start a timer job on the smart LED.
+//
+// Deprecated: use CronAddAfter instead.
 func (y *Yeelight) CronAdd(t, value string) (string, error) {
-	cmd := `{"id":12,"method":"cron_add","params":[` + t + `,` + value + `]}`
-	return y.request(cmd)
+	cronType, err := strconv.Atoi(t)
+	if err != nil {
+		return "", err
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil {
+		return "", err
+	}
+	return y.CronAddAfter(cronType, time.Duration(minutes)*time.Minute)
+}
+
+// CronGetType method is used to retrieve the setting of the current cron job of the specified type.
+func (y *Yeelight) CronGetType(cronType int) (string, error) {
+	return y.send(y.command("cron_get", cronType))
 }
 
 // CronGet method is used to retrieve the setting of the current cron job of the specified type.
+//
+// Deprecated: use CronGetType instead.
 func (y *Yeelight) CronGet(t string) (string, error) {
-	cmd := `{"id":13,"method":"cron_get","params":[` + t + `]}`
-	return y.request(cmd)
+	cronType, err := strconv.Atoi(t)
+	if err != nil {
+		return "", err
+	}
+	return y.CronGetType(cronType)
+}
+
+// CronDelType method is used to stop the specified cron job.
+func (y *Yeelight) CronDelType(cronType int) (string, error) {
+	return y.send(y.command("cron_del", cronType))
 }
 
 // CronDel method is used to stop the specified cron job.
+//
+// Deprecated: use CronDelType instead.
 func (y *Yeelight) CronDel(t string) (string, error) {
-	cmd := `{"id":14,"method":"cron_del","params":[` + t + `]}`
-	return y.request(cmd)
+	cronType, err := strconv.Atoi(t)
+	if err != nil {
+		return "", err
+	}
+	return y.CronDelType(cronType)
+}
+
+// Adjust method is used to change brightness, CT or color of a smart LED
+// without knowing the current value; it's mainly used by controllers.
+func (y *Yeelight) Adjust(action AdjustAction, prop AdjustProp) (string, error) {
+	return y.send(y.command("set_adjust", string(action), string(prop)))
 }
 
 // SetAdjust method is used to change brightness, CT or color of a smart LED
 // without knowing the current value, it's main used by controllers.
+//
+// Deprecated: use Adjust instead.
 func (y *Yeelight) SetAdjust(action, prop string) (string, error) {
-	cmd := `{"id":15,"method":"set_adjust","params":["` + action + `","` + prop + `"]}`
-	return y.request(cmd)
+	return y.Adjust(AdjustAction(action), AdjustProp(prop))
 }
 
 // SetName method is used to name the device. The name will be stored on the
 // device and reported in discovering response. User can also read the name
 // through “get_prop” method
 func (y *Yeelight) SetName(name string) (string, error) {
-	cmd := `{"id":16,"method":"set_name","params":["` + name + `"]}`
-	return y.request(cmd)
+	return y.send(y.command("set_name", name))
 }
 
 // On method is used to switch on the smart LED
@@ -0,0 +1,121 @@
+package goyeelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// musicAcceptTimeout bounds how long StartMusic waits for the bulb to
+// dial back and open its side of the music-mode connection.
+const musicAcceptTimeout = 10 * time.Second
+
+// StartMusic switches the bulb into music mode. It opens a TCP listener
+// on localAddr (or an ephemeral port on the interface used to reach the
+// bulb, if localAddr is empty), tells the bulb to connect back to it via
+// set_music, and accepts that single inbound connection. From then on
+// every command is sent over that reverse connection instead of being
+// subject to the bulb's usual 60 requests/minute quota, which unlocks
+// high-rate use cases such as audio-driven color flow.
+func (y *Yeelight) StartMusic(localAddr string) error {
+	ln, err := net.Listen("tcp", localAddr)
+	if err != nil {
+		return err
+	}
+
+	host, port, err := y.musicCallbackAddr(ln)
+	if err != nil {
+		ln.Close()
+		return err
+	}
+
+	if _, err := y.send(y.command("set_music", 1, host, port)); err != nil {
+		ln.Close()
+		return err
+	}
+
+	if tl, ok := ln.(*net.TCPListener); ok {
+		tl.SetDeadline(time.Now().Add(musicAcceptTimeout))
+	}
+	conn, err := ln.Accept()
+	ln.Close()
+	if err != nil {
+		return err
+	}
+
+	y.mu.Lock()
+	y.musicConn = conn
+	y.mu.Unlock()
+
+	return nil
+}
+
+// StopMusic reverts the bulb to normal mode and closes the reverse
+// connection opened by StartMusic.
+func (y *Yeelight) StopMusic() error {
+	y.mu.Lock()
+	conn := y.musicConn
+	y.musicConn = nil
+	y.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(y.command("set_music", 0))
+	if err == nil {
+		fmt.Fprint(conn, string(data)+"\r\n")
+	}
+
+	return conn.Close()
+}
+
+// requestMusic writes cmd to the music-mode reverse connection. Per the
+// Yeelight spec the bulb never replies on this channel, so the call must
+// not block waiting for one; it returns immediately with a synthesized
+// empty success result.
+func (y *Yeelight) requestMusic(conn net.Conn, cmd string) (string, error) {
+	if _, err := fmt.Fprint(conn, cmd+"\r\n"); err != nil {
+		return "", err
+	}
+	return "[]", nil
+}
+
+// musicCallbackAddr returns the host/port the bulb should dial back to
+// reach ln. If ln is bound to a wildcard address, the host is taken from
+// the local interface the OS would use to reach the bulb.
+func (y *Yeelight) musicCallbackAddr(ln net.Listener) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(ln.Addr().String())
+	if err != nil {
+		return "", 0, err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host, err = y.outboundAddr()
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
+
+// outboundAddr returns the local address of the interface the OS would
+// use to reach the bulb, for auto-picking a callback host when
+// StartMusic is given an empty localAddr.
+func (y *Yeelight) outboundAddr() (string, error) {
+	conn, err := net.Dial("udp", y.host+":"+y.port)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	host, _, err := net.SplitHostPort(conn.LocalAddr().String())
+	return host, err
+}
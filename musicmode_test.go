@@ -0,0 +1,54 @@
+package goyeelight
+
+import (
+	"net"
+	"testing"
+)
+
+func TestMusicCallbackAddrExplicitHost(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer ln.Close()
+
+	y := New("127.0.0.1", "1234")
+	host, port, err := y.musicCallbackAddr(ln)
+	if err != nil {
+		t.Fatalf("musicCallbackAddr: %v", err)
+	}
+	if host != "127.0.0.1" {
+		t.Errorf("host = %q, want 127.0.0.1", host)
+	}
+	if port == 0 {
+		t.Error("port = 0, want the listener's ephemeral port")
+	}
+}
+
+func TestMusicCallbackAddrWildcardFallsBackToOutboundAddr(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer ln.Close()
+
+	y := New("127.0.0.1", "1234")
+	host, _, err := y.musicCallbackAddr(ln)
+	if err != nil {
+		t.Fatalf("musicCallbackAddr: %v", err)
+	}
+	if host == "" || host == "0.0.0.0" {
+		t.Errorf("host = %q, want a concrete outbound address", host)
+	}
+}
+
+func TestOutboundAddr(t *testing.T) {
+	y := New("127.0.0.1", "1234")
+	host, err := y.outboundAddr()
+	if err != nil {
+		t.Fatalf("outboundAddr: %v", err)
+	}
+	if host == "" {
+		t.Error("outboundAddr returned an empty host")
+	}
+}
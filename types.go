@@ -0,0 +1,50 @@
+package goyeelight
+
+// Effect controls how a state transition requested via a set_* method is
+// visually applied on the bulb: immediately, or smoothly over a duration.
+type Effect string
+
+const (
+	EffectSudden Effect = "sudden"
+	EffectSmooth Effect = "smooth"
+)
+
+// CfAction is the action the bulb takes once a color flow started via
+// StartCf finishes or is stopped.
+type CfAction int
+
+const (
+	CfActionRecover CfAction = 0 // restore the state from before the flow
+	CfActionStay    CfAction = 1 // stay at the state of the last flow transition
+	CfActionOff     CfAction = 2 // turn off
+)
+
+// SceneClass selects which kind of state SetScene applies.
+type SceneClass string
+
+const (
+	SceneColor        SceneClass = "color"
+	SceneHSV          SceneClass = "hsv"
+	SceneCT           SceneClass = "ct"
+	SceneCf           SceneClass = "cf"
+	SceneAutoDelayOff SceneClass = "auto_delay_off"
+)
+
+// AdjustAction is the direction SetAdjust changes a property in.
+type AdjustAction string
+
+const (
+	AdjustIncrease AdjustAction = "increase"
+	AdjustDecrease AdjustAction = "decrease"
+	AdjustCircle   AdjustAction = "circle"
+)
+
+// AdjustProp is the property SetAdjust changes. AdjustCircle is only
+// valid with AdjustColor.
+type AdjustProp string
+
+const (
+	AdjustBright AdjustProp = "bright"
+	AdjustCT     AdjustProp = "ct"
+	AdjustColor  AdjustProp = "color"
+)